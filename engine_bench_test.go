@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// echoServer spins up a local server that always returns a small fixed body,
+// so these benchmarks measure engine/transport overhead rather than network
+// or handler latency.
+func echoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "echo")
+		fmt.Fprint(w, "ok")
+	}))
+}
+
+func BenchmarkHTTPEngine(b *testing.B) {
+	server := echoServer()
+	defer server.Close()
+
+	engine, err := newHTTPEngine("", 40, false, false, 10*1024*1024)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, _, err := engine.Do("GET", server.URL, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFastHTTPEngine(b *testing.B) {
+	server := echoServer()
+	defer server.Close()
+
+	engine, err := newFastHTTPEngine(server.URL, 40, 10*1024*1024)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, _, err := engine.Do("GET", server.URL, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
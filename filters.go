@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// stringList is a repeatable flag.Value, e.g. -filter status:404 -filter words:123.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// filterSpec is a single parsed key:value clause from -filter/-match.
+type filterSpec struct {
+	key   string
+	value string
+}
+
+// parseFilterSpecs turns raw "key:value" flag values into filterSpecs,
+// silently skipping malformed entries.
+func parseFilterSpecs(raw []string) []filterSpec {
+	specs := make([]filterSpec, 0, len(raw))
+	for _, r := range raw {
+		parts := strings.SplitN(r, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		specs = append(specs, filterSpec{key: strings.ToLower(parts[0]), value: parts[1]})
+	}
+	return specs
+}
+
+// matches reports whether result satisfies a single ffuf-style filter clause.
+func (f filterSpec) matches(result Result) bool {
+	switch f.key {
+	case "status":
+		return strconv.Itoa(result.StatusCode) == f.value
+	case "size", "cl":
+		return strconv.FormatInt(result.ContentLength, 10) == f.value
+	case "words", "w":
+		return strconv.Itoa(result.Words) == f.value
+	case "lines", "l":
+		return strconv.Itoa(result.Lines) == f.value
+	default:
+		return false
+	}
+}
+
+// keepResult applies -filter (exclude on match) and -match (keep only on
+// match) semantics, mirroring ffuf.
+func keepResult(result Result, filters, matches []filterSpec) bool {
+	for _, f := range filters {
+		if f.matches(result) {
+			return false
+		}
+	}
+
+	if len(matches) == 0 {
+		return true
+	}
+	for _, m := range matches {
+		if m.matches(result) {
+			return true
+		}
+	}
+	return false
+}
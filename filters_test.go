@@ -0,0 +1,74 @@
+package main
+
+import "testing"
+
+func TestParseFilterSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []string
+		want []filterSpec
+	}{
+		{"empty", nil, []filterSpec{}},
+		{
+			"single clause",
+			[]string{"status:404"},
+			[]filterSpec{{key: "status", value: "404"}},
+		},
+		{
+			"lowercases the key but not the value",
+			[]string{"Status:404", "Words:AB"},
+			[]filterSpec{{key: "status", value: "404"}, {key: "words", value: "AB"}},
+		},
+		{
+			"skips malformed entries without a colon",
+			[]string{"status:404", "nocolonhere", "words:12"},
+			[]filterSpec{{key: "status", value: "404"}, {key: "words", value: "12"}},
+		},
+		{
+			"only splits on the first colon",
+			[]string{"size:12:34"},
+			[]filterSpec{{key: "size", value: "12:34"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseFilterSpecs(tc.raw)
+			if len(got) != len(tc.want) {
+				t.Fatalf("parseFilterSpecs() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("spec[%d] = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestKeepResult(t *testing.T) {
+	result := Result{StatusCode: 200, ContentLength: 1234, Words: 10, Lines: 5}
+
+	tests := []struct {
+		name    string
+		filters []filterSpec
+		matches []filterSpec
+		want    bool
+	}{
+		{"no filters or matches keeps everything", nil, nil, true},
+		{"filter excludes a matching result", []filterSpec{{key: "status", value: "200"}}, nil, false},
+		{"filter leaves a non-matching result", []filterSpec{{key: "status", value: "403"}}, nil, true},
+		{"match keeps a matching result", nil, []filterSpec{{key: "words", value: "10"}}, true},
+		{"match drops a non-matching result", nil, []filterSpec{{key: "words", value: "99"}}, false},
+		{"filter wins even if match would also keep it", []filterSpec{{key: "status", value: "200"}}, []filterSpec{{key: "words", value: "10"}}, false},
+		{"unknown filter key never matches, so it can't exclude", []filterSpec{{key: "bogus", value: "200"}}, nil, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := keepResult(result, tc.filters, tc.matches); got != tc.want {
+				t.Errorf("keepResult() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Diff bitmask flags, one bit per fingerprint field that can diverge from baseline.
+const (
+	DiffStatus = 1 << iota
+	DiffContentLength
+	DiffWords
+	DiffLines
+	DiffBody
+)
+
+// Fingerprint captures enough of a response to tell whether a header or
+// bypass payload actually changed the application's behavior, rather than
+// just its status code.
+type Fingerprint struct {
+	StatusCode    int
+	ContentLength int64
+	Words         int
+	Lines         int
+	BodyHash      string
+	Truncated     bool // true if the body was cut off at -max-body
+}
+
+// computeFingerprint builds a Fingerprint from a response status code and
+// body. cacheBuster is stripped from the body first, since a target that
+// reflects the request URL would otherwise never match the baseline.
+// truncated carries through from the engine's -max-body read so callers know
+// the Words/Lines/BodyHash fields only cover a prefix of the real body.
+func computeFingerprint(statusCode int, body []byte, cacheBuster string, truncated bool) Fingerprint {
+	normalized := normalizeBody(body, cacheBuster)
+	hash := sha256.Sum256(normalized)
+
+	return Fingerprint{
+		StatusCode:    statusCode,
+		ContentLength: int64(len(body)),
+		Words:         len(strings.Fields(string(normalized))),
+		Lines:         bytes.Count(normalized, []byte("\n")) + 1,
+		BodyHash:      hex.EncodeToString(hash[:]),
+		Truncated:     truncated,
+	}
+}
+
+// normalizeBody strips the cachebuster token (if reflected anywhere in the
+// body) so it doesn't pollute the fingerprint hash/word/line counts.
+func normalizeBody(body []byte, cacheBuster string) []byte {
+	if cacheBuster == "" {
+		return body
+	}
+	return bytes.ReplaceAll(body, []byte(cacheBuster), nil)
+}
+
+// diffMask compares a fingerprint against the baseline and returns a bitmask
+// of every field that differs.
+func diffMask(baseline, current Fingerprint) int {
+	mask := 0
+	if current.StatusCode != baseline.StatusCode {
+		mask |= DiffStatus
+	}
+	if current.ContentLength != baseline.ContentLength {
+		mask |= DiffContentLength
+	}
+	if current.Words != baseline.Words {
+		mask |= DiffWords
+	}
+	if current.Lines != baseline.Lines {
+		mask |= DiffLines
+	}
+	if current.BodyHash != baseline.BodyHash {
+		mask |= DiffBody
+	}
+	return mask
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestDiffMask(t *testing.T) {
+	baseline := Fingerprint{StatusCode: 200, ContentLength: 100, Words: 10, Lines: 5, BodyHash: "aaa"}
+
+	tests := []struct {
+		name    string
+		current Fingerprint
+		want    int
+	}{
+		{"identical", baseline, 0},
+		{"status differs", Fingerprint{StatusCode: 403, ContentLength: 100, Words: 10, Lines: 5, BodyHash: "aaa"}, DiffStatus},
+		{"content length differs", Fingerprint{StatusCode: 200, ContentLength: 200, Words: 10, Lines: 5, BodyHash: "aaa"}, DiffContentLength},
+		{"words differ", Fingerprint{StatusCode: 200, ContentLength: 100, Words: 11, Lines: 5, BodyHash: "aaa"}, DiffWords},
+		{"lines differ", Fingerprint{StatusCode: 200, ContentLength: 100, Words: 10, Lines: 6, BodyHash: "aaa"}, DiffLines},
+		{"body hash differs", Fingerprint{StatusCode: 200, ContentLength: 100, Words: 10, Lines: 5, BodyHash: "bbb"}, DiffBody},
+		{
+			"everything differs",
+			Fingerprint{StatusCode: 500, ContentLength: 1, Words: 1, Lines: 1, BodyHash: "ccc"},
+			DiffStatus | DiffContentLength | DiffWords | DiffLines | DiffBody,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := diffMask(baseline, tc.current); got != tc.want {
+				t.Errorf("diffMask() = %b, want %b", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestComputeFingerprintStripsCacheBuster(t *testing.T) {
+	body := []byte("hello cachebuster123 world\nsecond line")
+	fp := computeFingerprint(200, body, "cachebuster123", false)
+
+	if fp.Words != 4 {
+		t.Errorf("Words = %d, want 4 (cachebuster should be stripped before counting)", fp.Words)
+	}
+	if fp.Lines != 2 {
+		t.Errorf("Lines = %d, want 2", fp.Lines)
+	}
+	if fp.ContentLength != int64(len(body)) {
+		t.Errorf("ContentLength = %d, want %d (measured on the raw body, not the normalized one)", fp.ContentLength, len(body))
+	}
+	if fp.Truncated {
+		t.Error("Truncated = true, want false")
+	}
+}
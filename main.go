@@ -1,18 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
-	"strconv"
+	"os/signal"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/fatih/color"
-	"io"
 	"math/rand"
 	"os"
 	"bufio"
@@ -23,6 +22,26 @@ type Result struct {
 	Header        string
 	StatusCode    int
 	ContentLength int64
+	Words         int
+	Lines         int
+	BodyHash      string
+	Diff          int    // bitmask of Diff* fields that differ from the baseline fingerprint
+	Category      string // set in -bypass mode: "header", "path", or "method"
+	Duration      time.Duration
+	ServerHeader  string
+	ViaHeader     string
+	SetCookie     string
+	Truncated     bool // true if the body was cut off at -max-body
+}
+
+// RequestOutcome bundles everything a single request yields: the exact URL
+// requested (including the cachebuster actually sent), the raw response, its
+// computed Fingerprint, and how long it took.
+type RequestOutcome struct {
+	URL         string
+	Response    *http.Response
+	Fingerprint Fingerprint
+	Duration    time.Duration
 }
 
 func main() {
@@ -33,7 +52,25 @@ func main() {
 	foundOnlyPtr := flag.Bool("found", false, "Print only headers with status code 200")
 	noConcurrentPtr := flag.Bool("no-concurrent", false, "Disable concurrent requests, send one request at a time") // Correctly added the flag here
 	quietPtr := flag.Bool("q", false, "Suppress banner")
+	bypassPtr := flag.Bool("bypass", false, "Run the built-in 403/401 bypass payload library instead of a headers file")
+	bypassIPPtr := flag.String("bypassIp", "", "IP/hostname to substitute into IP-based bypass headers (default cycles 127.0.0.1, localhost, 10.0.0.1)")
+	var filterList, matchList stringList
+	flag.Var(&filterList, "filter", "Exclude results matching key:value (status, size, words, lines); repeatable")
+	flag.Var(&matchList, "match", "Only include results matching key:value (status, size, words, lines); repeatable")
+	outputPtr := flag.String("output", "", "Write results to this file instead of (in addition to) the colored console view")
+	outputFormatPtr := flag.String("of", "jsonl", "Output format when -output is set: json, jsonl, csv, or md")
+	resumePtr := flag.Bool("resume", false, "Skip header lines already marked complete in the run's .state file")
+	threadsPtr := flag.Int("threads", 40, "Number of concurrent workers")
+	ratePtr := flag.Float64("rate", 0, "Max requests/sec across all workers, 0 = unlimited")
+	ipListPtr := flag.String("ip-list", "", "File of IPs/hostnames for {{IP}} to round-robin through (falls back to -bypassIp)")
+	modePtr := flag.String("mode", "clusterbomb", "How {{FILE:path}} lists combine when a header line references more than one: clusterbomb or pitchfork")
+	enginePtr := flag.String("engine", "net/http", "HTTP engine to use: net/http or fasthttp")
+	disableCompressionPtr := flag.Bool("disable-compression", false, "Disable transparent response compression (net/http engine only)")
+	http2Ptr := flag.Bool("http2", false, "Force-attempt HTTP/2 (net/http engine only)")
+	maxBodyPtr := flag.Int64("max-body", 10*1024*1024, "Maximum response body bytes to read and hash before truncating")
 	flag.Parse()
+	filters := parseFilterSpecs(filterList)
+	matches := parseFilterSpecs(matchList)
 	log.SetFlags(0)
 	
 	// Print tool banner
@@ -55,6 +92,23 @@ func main() {
 		return
 	}
 
+	bypassThreads := *threadsPtr
+	if *noConcurrentPtr {
+		bypassThreads = 1
+	}
+
+	if *bypassPtr {
+		engine, err := newEngine(*enginePtr, *urlPtr, *proxyPtr, bypassThreads, *disableCompressionPtr, *http2Ptr, *maxBodyPtr)
+		if err != nil {
+			fmt.Println("Error building HTTP engine:", err)
+			return
+		}
+		limiter := newRateLimiter(*ratePtr)
+		results := runBypassMode(engine, *urlPtr, *bypassIPPtr, *delayPtr, bypassThreads, limiter)
+		printResults(results, *foundOnlyPtr, filters, matches, *outputPtr, *outputFormatPtr)
+		return
+	}
+
 	if *headersFilePtr == "" {
 		fmt.Println("Please provide a valid headers file using the -headers flag")
 		return
@@ -66,63 +120,91 @@ func main() {
 		return
 	}
 
-	var wg sync.WaitGroup
-	results := make(chan Result)
+	headers, err = expandFileReferences(headers, *modePtr)
+	if err != nil {
+		fmt.Println("Error expanding {{FILE:...}} placeholders:", err)
+		return
+	}
 
-	if *noConcurrentPtr {
-		// Sequential requests (one at a time)
-		for _, header := range headers {
-			wg.Add(1)
-			go func(header string) {
-				defer wg.Done()
-
-				response, err := makeRequest(*urlPtr, header, *proxyPtr, *delayPtr)
-				if err != nil {
-					return
-				}
-
-				result := Result{
-					URL:           *urlPtr + "?cachebuster=" + generateCacheBuster(),
-					Header:        header,
-					StatusCode:    response.StatusCode,
-					ContentLength: response.ContentLength,
-				}
-				results <- result
-			}(header)
-			
-			// Wait for this request to finish before sending the next one
-			wg.Wait()
+	ips := []string{}
+	if *ipListPtr != "" {
+		ips, err = readHeadersFromFile(*ipListPtr)
+		if err != nil {
+			fmt.Println("Error reading -ip-list:", err)
+			return
+		}
+	} else if *bypassIPPtr != "" {
+		ips = []string{*bypassIPPtr}
+	}
+	tmpl, err := newTemplateContext(*urlPtr, ips)
+	if err != nil {
+		fmt.Println("Error parsing URL for templating:", err)
+		return
+	}
+
+	headersHash, err := headersFileHash(*headersFilePtr)
+	if err != nil {
+		fmt.Println("Error hashing headers file:", err)
+		return
+	}
+	statePath := stateFilePath(*urlPtr, headersHash)
+
+	if *resumePtr {
+		completed, err := loadCompletedHeaders(statePath)
+		if err != nil {
+			fmt.Println("Error reading state file:", err)
+			return
 		}
-	} else {
-		// Concurrent requests (default behavior)
+		remaining := headers[:0]
 		for _, header := range headers {
-			wg.Add(1)
-			go func(header string) {
-				defer wg.Done()
-
-				response, err := makeRequest(*urlPtr, header, *proxyPtr, *delayPtr)
-				if err != nil {
-					return
-				}
-
-				result := Result{
-					URL:           *urlPtr + "?cachebuster=" + generateCacheBuster(),
-					Header:        header,
-					StatusCode:    response.StatusCode,
-					ContentLength: response.ContentLength,
-				}
-				results <- result
-			}(header)
+			if !completed[header] {
+				remaining = append(remaining, header)
+			}
 		}
+		headers = remaining
+	}
+
+	state, err := newStateWriter(statePath, *resumePtr)
+	if err != nil {
+		fmt.Println("Error opening state file:", err)
+		return
+	}
+	defer state.Close()
 
-		// Close the results channel after all requests are done
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
+	threads := *threadsPtr
+	if *noConcurrentPtr {
+		threads = 1
 	}
 
-	printResults(results, *foundOnlyPtr)
+	engine, err := newEngine(*enginePtr, *urlPtr, *proxyPtr, threads, *disableCompressionPtr, *http2Ptr, *maxBodyPtr)
+	if err != nil {
+		fmt.Println("Error building HTTP engine:", err)
+		return
+	}
+
+	outcome, err := makeRequest(engine, *urlPtr, "", 0, nil)
+	if err != nil {
+		fmt.Println("Error making baseline request:", err)
+		return
+	}
+	baseline := outcome.Fingerprint
+
+	limiter := newRateLimiter(*ratePtr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		if _, ok := <-sigCh; ok {
+			fmt.Println("\nInterrupted, finishing in-flight requests and flushing results...")
+			cancel()
+		}
+	}()
+
+	results := runWorkerPool(ctx, engine, *urlPtr, headers, *delayPtr, threads, limiter, baseline, state, tmpl)
+
+	printResults(results, *foundOnlyPtr, filters, matches, *outputPtr, *outputFormatPtr)
 }
 
 func readHeadersFromFile(filename string) ([]string, error) {
@@ -145,59 +227,55 @@ func readHeadersFromFile(filename string) ([]string, error) {
 	return headers, nil
 }
 
-func makeRequest(baseURL, header, proxy string, delay int) (*http.Response, error) {
-	// Apply delay before making the request
-	if delay > 0 {
-		time.Sleep(time.Duration(delay) * time.Second)
+func makeRequest(engine Engine, baseURL, header string, delay int, tmpl *templateContext) (RequestOutcome, error) {
+	if tmpl != nil {
+		header = tmpl.render(header)
 	}
 
-	urlWithBuster := baseURL + "?cachebuster=" + generateCacheBuster()  // Adds a cachebuster query parameter
-	headers := parseHeaders(header)  // Parses the headers into a slice of strings
-
-	// Create a new HTTP GET request
-	req, err := http.NewRequest("GET", urlWithBuster, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Add the parsed headers to the request
-	for _, h := range headers {
+	headers := make(map[string]string)
+	for _, h := range parseHeaders(header) {
 		parts := strings.SplitN(h, ": ", 2)
 		if len(parts) == 2 {
-			req.Header.Add(parts[0], parts[1])
+			headers[parts[0]] = parts[1]
 		}
 	}
 
-	// Create an HTTP client
-	client := &http.Client{}
-	if proxy != "" {
-		// If a proxy is provided, configure the client to use it
-		proxyURL, err := url.Parse("http://" + proxy)
-		if err != nil {
-			fmt.Println("Error parsing proxy URL:", err)
-			return nil, err
-		}
-		transport := &http.Transport{Proxy: http.ProxyURL(proxyURL)}
-		client = &http.Client{Transport: transport}
+	return makeRequestWithMethod(engine, baseURL, "GET", headers, delay)
+}
+
+// makeRequestWithMethod sends a single HTTP request through engine, adding a
+// cachebuster query parameter and the given headers. It underlies both the
+// default header-fuzzing mode and the -bypass payload library. The returned
+// Fingerprint is computed from the full response body so callers can diff
+// it against a baseline.
+func makeRequestWithMethod(engine Engine, baseURL, method string, headers map[string]string, delay int) (RequestOutcome, error) {
+	// Apply delay before making the request
+	if delay > 0 {
+		time.Sleep(time.Duration(delay) * time.Second)
 	}
 
-	// Send the HTTP request and return the response
-	response, err := client.Do(req)
+	cacheBuster := generateCacheBuster()
+	urlWithBuster := baseURL + "?cachebuster=" + cacheBuster // Adds a cachebuster query parameter
+
+	start := time.Now()
+	status, respHeaders, body, truncated, err := engine.Do(method, urlWithBuster, headers)
+	duration := time.Since(start)
 	if err != nil {
-		return nil, err
+		return RequestOutcome{}, err
 	}
 
-	// Check if the content length is available, read body if necessary
-	if response.ContentLength >= 0 {
-		return response, nil
+	response := &http.Response{
+		StatusCode:    status,
+		Header:        respHeaders,
+		ContentLength: int64(len(body)),
 	}
 
-	// If ContentLength is not provided, read the response body to calculate it
-	body, err := io.ReadAll(response.Body)
-	if err == nil {
-		response.ContentLength = int64(len(body))
-	}
-	return response, nil
+	return RequestOutcome{
+		URL:         urlWithBuster,
+		Response:    response,
+		Fingerprint: computeFingerprint(status, body, cacheBuster, truncated),
+		Duration:    duration,
+	}, nil
 }
 
 func parseHeaders(header string) []string {
@@ -205,58 +283,101 @@ func parseHeaders(header string) []string {
 }
 
 func generateCacheBuster() string {
+	return randomAlnum(10)
+}
+
+// randomAlnum returns n random alphanumeric characters, backing both the
+// cachebuster and the {{RAND:n}} template placeholder.
+func randomAlnum(n int) string {
 	rand.Seed(time.Now().UnixNano())
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, 10)
+	b := make([]byte, n)
 	for i := range b {
 		b[i] = charset[rand.Intn(len(charset))]
 	}
 	return string(b)
 }
 
-func applyBackoff(response *http.Response, backoff int) int {
-	retryAfter := response.Header.Get("Retry-After")
-	if retryAfter != "" {
-		if retrySecs, err := strconv.Atoi(retryAfter); err == nil {
-			time.Sleep(time.Duration(retrySecs) * time.Second)
-			return backoff
+func printResults(results <-chan Result, foundOnly bool, filters, matches []filterSpec, outputPath, outputFormat string) {
+	var all []Result
+	for result := range results {
+		// Only print results with status code 200 if the -found flag is set
+		if foundOnly && result.StatusCode != 200 {
+			continue
+		}
+		if !keepResult(result, filters, matches) {
+			continue
 		}
+		all = append(all, result)
 	}
 
-	// Exponential backoff if Retry-After is not specified
-	time.Sleep(time.Duration(backoff) * time.Second)
-	return backoff * 2 // Double the backoff time for the next request
+	if outputPath != "" {
+		if err := writeStructuredOutput(all, outputPath, outputFormat); err != nil {
+			fmt.Println("Error writing output file:", err)
+		}
+		return
+	}
+
+	grouped := make(map[string][]Result)
+	var order []string
+	for _, result := range all {
+		category := result.Category
+		if _, ok := grouped[category]; !ok {
+			order = append(order, category)
+		}
+		grouped[category] = append(grouped[category], result)
+	}
+
+	bold := color.New(color.Bold).SprintFunc()
+	for _, category := range order {
+		if category != "" {
+			fmt.Println(bold(fmt.Sprintf("== %s ==", category)))
+		}
+		for _, result := range grouped[category] {
+			printResultLine(result)
+		}
+	}
 }
 
-func printResults(results <-chan Result, foundOnly bool) {
+func printResultLine(result Result) {
 	red := color.New(color.FgRed).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
 	magenta := color.New(color.FgMagenta).SprintFunc()
 	yellow := color.New(color.FgYellow).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
 
-	for result := range results {
-		// Only print results with status code 200 if the -found flag is set
-		if foundOnly && result.StatusCode != 200 {
-			continue
-		}
-
-		statusColorFunc := red
-		if result.StatusCode == 200 {
-			statusColorFunc = green
-		}
-
-		statusOutput := statusColorFunc(fmt.Sprintf("[%d]", result.StatusCode))
-		contentLengthOutput := magenta(fmt.Sprintf("[CL: %d]", result.ContentLength))
-		headerOutput := cyan(fmt.Sprintf("[%s]", result.Header))
+	statusColorFunc := red
+	if result.StatusCode == 200 {
+		statusColorFunc = green
+	}
 
-		parsedURL, _ := url.Parse(result.URL)
-		query := parsedURL.Query()
-		query.Del("cachebuster")
-		parsedURL.RawQuery = query.Encode()
-		urlOutput := yellow(fmt.Sprintf("[%s]", parsedURL.String()))
+	statusOutput := statusColorFunc(fmt.Sprintf("[%d]", result.StatusCode))
+	contentLengthOutput := diffColor(result.Diff, DiffContentLength, magenta)(fmt.Sprintf("[CL: %d]", result.ContentLength))
+	wordsOutput := diffColor(result.Diff, DiffWords, magenta)(fmt.Sprintf("[W: %d]", result.Words))
+	linesOutput := diffColor(result.Diff, DiffLines, magenta)(fmt.Sprintf("[L: %d]", result.Lines))
+	headerOutput := cyan(fmt.Sprintf("[%s]", result.Header))
+
+	parsedURL, _ := url.Parse(result.URL)
+	query := parsedURL.Query()
+	query.Del("cachebuster")
+	parsedURL.RawQuery = query.Encode()
+	urlOutput := yellow(fmt.Sprintf("[%s]", parsedURL.String()))
+
+	resultOutput := fmt.Sprintf("%s %s %s %s %s %s", statusOutput, contentLengthOutput, wordsOutput, linesOutput, headerOutput, urlOutput)
+	if result.Diff != 0 {
+		resultOutput += " " + red("[DIFF]")
+	}
+	if result.Truncated {
+		resultOutput += " " + yellow("[TRUNC]")
+	}
+	fmt.Println(resultOutput)
+}
 
-		resultOutput := fmt.Sprintf("%s %s %s %s", statusOutput, contentLengthOutput, headerOutput, urlOutput)
-		fmt.Println(resultOutput)
+// diffColor returns fallback unless bit is set in diff, in which case it
+// highlights the field red to call out a baseline divergence.
+func diffColor(diff, bit int, fallback func(a ...interface{}) string) func(a ...interface{}) string {
+	if diff&bit != 0 {
+		return color.New(color.FgRed, color.Bold).SprintFunc()
 	}
+	return fallback
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// resultRecord is the serialized form of a Result, used by every structured
+// output format.
+type resultRecord struct {
+	URL           string `json:"url"`
+	Header        string `json:"header"`
+	StatusCode    int    `json:"status_code"`
+	ContentLength int64  `json:"content_length"`
+	Words         int    `json:"words"`
+	Lines         int    `json:"lines"`
+	BodyHash      string `json:"body_hash"`
+	Diff          int    `json:"diff"`
+	Category      string `json:"category,omitempty"`
+	DurationMS    int64  `json:"duration_ms"`
+	Server        string `json:"server,omitempty"`
+	Via           string `json:"via,omitempty"`
+	SetCookie     string `json:"set_cookie,omitempty"`
+	Truncated     bool   `json:"truncated"`
+}
+
+func toRecord(r Result) resultRecord {
+	return resultRecord{
+		URL:           r.URL,
+		Header:        r.Header,
+		StatusCode:    r.StatusCode,
+		ContentLength: r.ContentLength,
+		Words:         r.Words,
+		Lines:         r.Lines,
+		BodyHash:      r.BodyHash,
+		Diff:          r.Diff,
+		Category:      r.Category,
+		DurationMS:    r.Duration.Milliseconds(),
+		Server:        r.ServerHeader,
+		Via:           r.ViaHeader,
+		SetCookie:     r.SetCookie,
+		Truncated:     r.Truncated,
+	}
+}
+
+// writeStructuredOutput serializes results to path in the given format
+// (json, jsonl, csv, or md).
+func writeStructuredOutput(results []Result, path, format string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch format {
+	case "json":
+		return writeJSON(file, results)
+	case "jsonl":
+		return writeJSONL(file, results)
+	case "csv":
+		return writeCSV(file, results)
+	case "md":
+		return writeMarkdown(file, results)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func writeJSON(file *os.File, results []Result) error {
+	records := make([]resultRecord, 0, len(results))
+	for _, r := range results {
+		records = append(records, toRecord(r))
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+func writeJSONL(file *os.File, results []Result) error {
+	encoder := json.NewEncoder(file)
+	for _, r := range results {
+		if err := encoder.Encode(toRecord(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(file *os.File, results []Result) error {
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	header := []string{"url", "header", "status_code", "content_length", "words", "lines", "body_hash", "diff", "category", "duration_ms", "server", "via", "set_cookie", "truncated"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		rec := toRecord(r)
+		row := []string{
+			rec.URL, rec.Header,
+			strconv.Itoa(rec.StatusCode), strconv.FormatInt(rec.ContentLength, 10),
+			strconv.Itoa(rec.Words), strconv.Itoa(rec.Lines), rec.BodyHash, strconv.Itoa(rec.Diff),
+			rec.Category, strconv.FormatInt(rec.DurationMS, 10), rec.Server, rec.Via, rec.SetCookie,
+			strconv.FormatBool(rec.Truncated),
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMarkdown(file *os.File, results []Result) error {
+	fmt.Fprintln(file, "| Status | CL | Words | Lines | Diff | Truncated | Header | Duration (ms) | URL |")
+	fmt.Fprintln(file, "|---|---|---|---|---|---|---|---|---|")
+	for _, r := range results {
+		fmt.Fprintf(file, "| %d | %d | %d | %d | %d | %t | %s | %d | %s |\n",
+			r.StatusCode, r.ContentLength, r.Words, r.Lines, r.Diff, r.Truncated, r.Header, r.Duration.Milliseconds(), r.URL)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a simple shared token-bucket limiter: Wait() paces callers
+// to -rate requests/sec, and Pause() lets any caller hold back every other
+// caller for a while (used when a target starts returning 429/503).
+type rateLimiter struct {
+	interval time.Duration // 0 means unlimited
+
+	mu          sync.Mutex
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimiter(reqsPerSec float64) *rateLimiter {
+	rl := &rateLimiter{}
+	if reqsPerSec > 0 {
+		rl.interval = time.Duration(float64(time.Second) / reqsPerSec)
+	}
+	return rl
+}
+
+// Wait blocks until the caller is allowed to send its next request, honoring
+// both the configured rate and any outstanding Pause.
+func (rl *rateLimiter) Wait() {
+	rl.mu.Lock()
+	if wait := time.Until(rl.pausedUntil); wait > 0 {
+		rl.mu.Unlock()
+		time.Sleep(wait)
+		rl.mu.Lock()
+	}
+
+	if rl.interval > 0 {
+		now := time.Now()
+		next := rl.last.Add(rl.interval)
+		if next.After(now) {
+			wait := next.Sub(now)
+			rl.mu.Unlock()
+			time.Sleep(wait)
+			rl.mu.Lock()
+		}
+		rl.last = time.Now()
+	}
+	rl.mu.Unlock()
+}
+
+// Pause holds back every caller of Wait for d, extending any pause already
+// in effect rather than shortening it.
+func (rl *rateLimiter) Pause(d time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if until := time.Now().Add(d); until.After(rl.pausedUntil) {
+		rl.pausedUntil = until
+	}
+}
+
+// applyBackoff pauses the shared limiter when a response signals it's being
+// rate limited: for the Retry-After duration if the target sent one,
+// otherwise for backoffSecs, which the caller should then double.
+func applyBackoff(limiter *rateLimiter, response *http.Response, backoffSecs int) int {
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil {
+			limiter.Pause(time.Duration(secs) * time.Second)
+			return backoffSecs
+		}
+	}
+
+	limiter.Pause(time.Duration(backoffSecs) * time.Second)
+	return backoffSecs * 2
+}
+
+// runWorkerPool fans headers out across a fixed number of workers sharing a
+// rate limiter, a baseline fingerprint, and a .state writer. It stops
+// dispatching new jobs (but lets in-flight ones finish and flush) once ctx
+// is canceled, so a SIGINT still yields a final, complete set of results.
+func runWorkerPool(ctx context.Context, engine Engine, urlStr string, headers []string, delay, threads int, limiter *rateLimiter, baseline Fingerprint, state *stateWriter, tmpl *templateContext) <-chan Result {
+	jobs := make(chan string)
+	results := make(chan Result)
+
+	go func() {
+		defer close(jobs)
+		for _, header := range headers {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- header:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			backoffSecs := 1
+			for header := range jobs {
+				limiter.Wait()
+
+				outcome, err := makeRequest(engine, urlStr, header, delay, tmpl)
+				if err != nil {
+					continue
+				}
+
+				if status := outcome.Response.StatusCode; status == 429 || status == 503 {
+					backoffSecs = applyBackoff(limiter, outcome.Response, backoffSecs)
+				} else {
+					backoffSecs = 1
+				}
+
+				result := Result{
+					URL:           outcome.URL,
+					Header:        header,
+					StatusCode:    outcome.Response.StatusCode,
+					ContentLength: outcome.Fingerprint.ContentLength,
+					Words:         outcome.Fingerprint.Words,
+					Lines:         outcome.Fingerprint.Lines,
+					BodyHash:      outcome.Fingerprint.BodyHash,
+					Diff:          diffMask(baseline, outcome.Fingerprint),
+					Duration:      outcome.Duration,
+					ServerHeader:  outcome.Response.Header.Get("Server"),
+					ViaHeader:     outcome.Response.Header.Get("Via"),
+					SetCookie:     outcome.Response.Header.Get("Set-Cookie"),
+					Truncated:     outcome.Fingerprint.Truncated,
+				}
+				// The reader in printResults keeps draining results until this
+				// channel closes, so the send below always has a receiver ready
+				// even after ctx is canceled. markDone only happens once that
+				// send actually lands, so a dropped ctx.Done race can never mark
+				// a header done without a result ever reaching an output.
+				results <- result
+				state.markDone(header)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
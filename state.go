@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// headersFileHash returns a hex SHA-256 of a headers file's contents, used
+// together with the target URL to key a resumable run's .state file.
+func headersFileHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// stateFilePath derives a stable .state file name from the (url, headers
+// file contents) pair, so re-running the exact same scan finds it again.
+func stateFilePath(targetURL, headersHash string) string {
+	key := sha256.Sum256([]byte(targetURL + "|" + headersHash))
+	return fmt.Sprintf(".headerpwn-%s.state", hex.EncodeToString(key[:])[:16])
+}
+
+// loadCompletedHeaders reads a .state file into a set of header lines that
+// have already been sent. A missing file just means nothing has run yet.
+func loadCompletedHeaders(path string) (map[string]bool, error) {
+	completed := make(map[string]bool)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return completed, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		completed[scanner.Text()] = true
+	}
+	return completed, scanner.Err()
+}
+
+// stateWriter appends completed header lines to the .state file, guarded by
+// a mutex since many workers call markDone concurrently.
+type stateWriter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// newStateWriter opens path for appending. When resume is false, any
+// previous state for this (url, headers file) pair is discarded first.
+func newStateWriter(path string, resume bool) (*stateWriter, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &stateWriter{file: file}, nil
+}
+
+func (s *stateWriter) markDone(header string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(s.file, header)
+}
+
+func (s *stateWriter) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// placeholderRe matches Burp-Intruder-style placeholders such as {{IP}},
+// {{RAND:10}}, {{B64:admin}}, and {{FILE:wordlist.txt}}.
+var placeholderRe = regexp.MustCompile(`\{\{([A-Z]+)(?::([^}]*))?\}\}`)
+
+// fileRe isolates just the {{FILE:path}} placeholders, which are expanded
+// into multiple concrete header lines before a run starts rather than
+// resolved per-request like the rest of the template vocabulary.
+var fileRe = regexp.MustCompile(`\{\{FILE:([^}]+)\}\}`)
+
+// templateContext resolves the per-request placeholders in a header line.
+// It is shared across all workers, so the IP round-robin is mutex-guarded.
+type templateContext struct {
+	host string
+
+	mu    sync.Mutex
+	ips   []string
+	ipPos int
+}
+
+// newTemplateContext builds a templateContext for targetURL. ips is the
+// pool {{IP}} round-robins through — a single bypassIP, the contents of
+// -ip-list, or nil if neither was given.
+func newTemplateContext(targetURL string, ips []string) (*templateContext, error) {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, err
+	}
+	return &templateContext{host: parsed.Host, ips: ips}, nil
+}
+
+func (tc *templateContext) nextIP() string {
+	if len(tc.ips) == 0 {
+		return ""
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	ip := tc.ips[tc.ipPos%len(tc.ips)]
+	tc.ipPos++
+	return ip
+}
+
+// render resolves every {{...}} placeholder in line except {{FILE:...}},
+// which is expanded ahead of time by expandFileReferences.
+func (tc *templateContext) render(line string) string {
+	return placeholderRe.ReplaceAllStringFunc(line, func(match string) string {
+		sub := placeholderRe.FindStringSubmatch(match)
+		name, arg := sub[1], sub[2]
+		switch name {
+		case "IP":
+			return tc.nextIP()
+		case "HOST":
+			return tc.host
+		case "UUID":
+			return generateUUID()
+		case "UNIX":
+			return strconv.FormatInt(time.Now().Unix(), 10)
+		case "RAND":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n <= 0 {
+				n = 10
+			}
+			return randomAlnum(n)
+		case "B64":
+			return base64.StdEncoding.EncodeToString([]byte(arg))
+		case "HEX":
+			return hex.EncodeToString([]byte(arg))
+		default:
+			return match
+		}
+	})
+}
+
+func generateUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// expandFileReferences turns every {{FILE:path}} placeholder in a headers
+// list into a full request per line of the referenced file, taking the
+// cartesian product (mode "clusterbomb") or zipping by index (mode
+// "pitchfork") when a single line references more than one file.
+func expandFileReferences(headers []string, mode string) ([]string, error) {
+	fileValues := make(map[string][]string)
+	var expanded []string
+
+	for _, line := range headers {
+		paths := uniqueFilePaths(line)
+		if len(paths) == 0 {
+			expanded = append(expanded, line)
+			continue
+		}
+
+		valueLists := make([][]string, len(paths))
+		for i, path := range paths {
+			values, ok := fileValues[path]
+			if !ok {
+				var err error
+				values, err = readHeadersFromFile(path)
+				if err != nil {
+					return nil, err
+				}
+				fileValues[path] = values
+			}
+			valueLists[i] = values
+		}
+
+		var combos [][]string
+		if mode == "pitchfork" {
+			combos = zipCombinations(valueLists)
+		} else {
+			combos = cartesianProduct(valueLists)
+		}
+
+		for _, combo := range combos {
+			rendered := line
+			for i, path := range paths {
+				rendered = strings.ReplaceAll(rendered, "{{FILE:"+path+"}}", combo[i])
+			}
+			expanded = append(expanded, rendered)
+		}
+	}
+
+	return expanded, nil
+}
+
+func uniqueFilePaths(line string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, m := range fileRe.FindAllStringSubmatch(line, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			paths = append(paths, m[1])
+		}
+	}
+	return paths
+}
+
+func cartesianProduct(lists [][]string) [][]string {
+	combos := [][]string{{}}
+	for _, list := range lists {
+		var next [][]string
+		for _, combo := range combos {
+			for _, value := range list {
+				next = append(next, append(append([]string{}, combo...), value))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// zipCombinations implements pitchfork mode: every list is walked in
+// lockstep, wrapping shorter lists around to match the longest one.
+func zipCombinations(lists [][]string) [][]string {
+	n := 0
+	for _, list := range lists {
+		if len(list) > n {
+			n = len(list)
+		}
+	}
+
+	combos := make([][]string, n)
+	for i := 0; i < n; i++ {
+		combo := make([]string, len(lists))
+		for j, list := range lists {
+			if len(list) == 0 {
+				continue
+			}
+			combo[j] = list[i%len(list)]
+		}
+		combos[i] = combo
+	}
+	return combos
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCartesianProduct(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists [][]string
+		want  [][]string
+	}{
+		{"single list", [][]string{{"a", "b"}}, [][]string{{"a"}, {"b"}}},
+		{
+			"two lists",
+			[][]string{{"a", "b"}, {"1", "2"}},
+			[][]string{{"a", "1"}, {"a", "2"}, {"b", "1"}, {"b", "2"}},
+		},
+		{"empty list yields no combinations", [][]string{{"a"}, {}}, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := cartesianProduct(tc.lists)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("cartesianProduct(%v) = %v, want %v", tc.lists, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestZipCombinations(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists [][]string
+		want  [][]string
+	}{
+		{
+			"equal length lists zip by index",
+			[][]string{{"a", "b"}, {"1", "2"}},
+			[][]string{{"a", "1"}, {"b", "2"}},
+		},
+		{
+			"shorter list wraps around to match the longest",
+			[][]string{{"a", "b", "c"}, {"1", "2"}},
+			[][]string{{"a", "1"}, {"b", "2"}, {"c", "1"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := zipCombinations(tc.lists)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("zipCombinations(%v) = %v, want %v", tc.lists, got, tc.want)
+			}
+		})
+	}
+}